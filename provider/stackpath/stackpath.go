@@ -22,10 +22,12 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
 	log "github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
 	"sigs.k8s.io/external-dns/endpoint"
 	"sigs.k8s.io/external-dns/plan"
 	"sigs.k8s.io/external-dns/provider"
@@ -34,15 +36,26 @@ import (
 	"github.com/wmarchesi123/stackpath-go/pkg/oauth2"
 )
 
+// defaultConcurrency bounds the number of zones fetched in parallel when no
+// StackPathConfig.Concurrency is supplied.
+const defaultConcurrency = 10
+
+// defaultPageSize is the number of items requested per page when no
+// StackPathConfig.PageSize is supplied.
+const defaultPageSize = 100
+
 type StackPathProvider struct {
 	provider.BaseProvider
-	client       *dns.APIClient
-	context      context.Context
-	domainFilter endpoint.DomainFilter
-	zoneIDFilter provider.ZoneIDFilter
-	stackID      string
-	dryRun       bool
-	testing      bool
+	client           *dns.APIClient
+	context          context.Context
+	domainFilter     endpoint.DomainFilter
+	zoneIDFilter     provider.ZoneIDFilter
+	stackID          string
+	dryRun           bool
+	testing          bool
+	concurrency      int
+	pageSize         int32
+	useLabelRegistry bool
 }
 
 type StackPathConfig struct {
@@ -51,6 +64,19 @@ type StackPathConfig struct {
 	ZoneIDFilter provider.ZoneIDFilter
 	DryRun       bool
 	Testing      bool
+	// Concurrency caps the number of zones whose records are fetched in
+	// parallel. Defaults to defaultConcurrency when unset.
+	Concurrency int
+	// PageSize is the number of items requested per page when paginating
+	// zones and zone records. Defaults to defaultPageSize when unset.
+	PageSize int32
+	// UseLabelRegistry stores external-dns ownership metadata (owner,
+	// resource) in StackPath's native record Labels instead of relying on
+	// the shared TXT registry, so the noop-registry can be used with this
+	// provider. This avoids doubling record count and the heritage=external-dns
+	// TXT clutter, at the cost of the ownership metadata being invisible to
+	// `dig` -- it only round-trips through this provider's Records/ApplyChanges.
+	UseLabelRegistry bool
 }
 
 func NewStackPathProvider(config StackPathConfig) (*StackPathProvider, error) {
@@ -86,14 +112,27 @@ func NewStackPathProvider(config StackPathConfig) (*StackPathProvider, error) {
 
 	client := dns.NewAPIClient(clientConfig)
 
+	concurrency := config.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+
+	pageSize := config.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+
 	provider := &StackPathProvider{
-		client:       client,
-		context:      authorizedContext,
-		domainFilter: config.DomainFilter,
-		zoneIDFilter: config.ZoneIDFilter,
-		stackID:      stackId,
-		dryRun:       config.DryRun,
-		testing:      config.Testing,
+		client:           client,
+		context:          authorizedContext,
+		domainFilter:     config.DomainFilter,
+		zoneIDFilter:     config.ZoneIDFilter,
+		stackID:          stackId,
+		dryRun:           config.DryRun,
+		testing:          config.Testing,
+		concurrency:      concurrency,
+		pageSize:         pageSize,
+		useLabelRegistry: config.UseLabelRegistry,
 	}
 
 	return provider, nil
@@ -112,24 +151,26 @@ func (p *StackPathProvider) Records(ctx context.Context) ([]*endpoint.Endpoint,
 		return nil, err
 	}
 
-	for _, zone := range zones {
-
-		recordsResponse, _, err := p.getZoneRecords(zone.GetId())
-		if err != nil {
-			return nil, err
-		}
-
-		records := recordsResponse.GetRecords()
+	zoneRecords, err := p.getAllZoneRecords(zones)
+	if err != nil {
+		return nil, err
+	}
 
-		for _, record := range records {
-			if provider.SupportedRecordType(record.GetType()) {
-				endpoints = append(endpoints, endpoint.NewEndpointWithTTL(
+	for i, zone := range zones {
+		for _, record := range zoneRecords[i] {
+			if stackPathSupportedRecordType(record.GetType()) {
+				ep := endpoint.NewEndpointWithTTL(
 					record.GetName()+"."+zone.GetDomain(),
 					record.GetType(),
 					endpoint.TTL(record.GetTtl()),
-					record.GetData(),
-				),
+					fromStackPathRecord(record),
 				)
+
+				if p.useLabelRegistry {
+					ep.Labels = ownershipLabelsFromRecord(record)
+				}
+
+				endpoints = append(endpoints, ep)
 			}
 		}
 	}
@@ -155,15 +196,13 @@ func (p *StackPathProvider) StackPathStyleRecords() ([]dns.ZoneZoneRecord, error
 		return nil, err
 	}
 
-	for _, zone := range zones {
-
-		recordsResponse, _, err := p.getZoneRecords(zone.GetId())
-		if err != nil {
-			return nil, err
-		}
-
-		records = append(records, recordsResponse.GetRecords()...)
+	zoneRecords, err := p.getAllZoneRecords(zones)
+	if err != nil {
+		return nil, err
+	}
 
+	for _, zr := range zoneRecords {
+		records = append(records, zr...)
 	}
 
 	out := "Found:"
@@ -175,13 +214,82 @@ func (p *StackPathProvider) StackPathStyleRecords() ([]dns.ZoneZoneRecord, error
 	return records, nil
 }
 
+// getAllZoneRecords fans out getZoneRecords across zones through a worker
+// pool bounded by p.concurrency, keeping StackPath rate limits in check on
+// accounts with many domains. The returned slice is indexed the same as
+// zones, so callers can rely on deterministic, zone-order results even
+// though the underlying fetches complete out of order.
+func (p *StackPathProvider) getAllZoneRecords(zones []dns.ZoneZone) ([][]dns.ZoneZoneRecord, error) {
+
+	results := make([][]dns.ZoneZoneRecord, len(zones))
+
+	g := new(errgroup.Group)
+	g.SetLimit(p.concurrency)
+
+	for i, zone := range zones {
+		i, zone := i, zone
+		g.Go(func() error {
+			recordsResponse, _, err := p.getZoneRecords(zone.GetId())
+			if err != nil {
+				return err
+			}
+			results[i] = recordsResponse.GetRecords()
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
 func (p *StackPathProvider) getZoneRecords(zoneID string) (dns.ZoneGetZoneRecordsResponse, *http.Response, error) {
 
 	if p.testing {
 		return dns.ZoneGetZoneRecordsResponse{}, nil, nil
 	}
 
-	return p.client.ResourceRecordsApi.GetZoneRecords(p.context, p.stackID, zoneID).Execute()
+	resp, err := paginateZoneRecords(p.pageSize, func(endCursor string) (dns.ZoneGetZoneRecordsResponse, *http.Response, error) {
+		req := p.client.ResourceRecordsApi.GetZoneRecords(p.context, p.stackID, zoneID).PageRequestFirst(p.pageSize)
+		if endCursor != "" {
+			req = req.PageRequestAfter(endCursor)
+		}
+		return req.Execute()
+	})
+
+	return resp, nil, err
+}
+
+// paginateZoneRecords drives fetchPage until PageInfo.HasNextPage is false,
+// aggregating Records across pages. fetchPage is given the EndCursor of the
+// previous page ("" for the first request).
+func paginateZoneRecords(pageSize int32, fetchPage func(endCursor string) (dns.ZoneGetZoneRecordsResponse, *http.Response, error)) (dns.ZoneGetZoneRecordsResponse, error) {
+
+	var allRecords []dns.ZoneZoneRecord
+	aggregated := dns.ZoneGetZoneRecordsResponse{}
+	endCursor := ""
+
+	for {
+		resp, _, err := fetchPage(endCursor)
+		if err != nil {
+			return dns.ZoneGetZoneRecordsResponse{}, err
+		}
+
+		allRecords = append(allRecords, resp.GetRecords()...)
+		aggregated = resp
+
+		pageInfo := resp.GetPageInfo()
+		if !pageInfo.GetHasNextPage() {
+			break
+		}
+		endCursor = pageInfo.GetEndCursor()
+	}
+
+	aggregated.Records = &allRecords
+
+	return aggregated, nil
 }
 
 func (p *StackPathProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
@@ -250,18 +358,12 @@ func (p *StackPathProvider) create(endpoints []*endpoint.Endpoint, zones *[]dns.
 
 func (p *StackPathProvider) createTarget(zoneID string, domain string, endpoint *endpoint.Endpoint, target string) error {
 
-	msg := dns.NewZoneUpdateZoneRecordMessage()
-	name := strings.TrimSuffix(endpoint.DNSName, "."+domain)
-	if name == "" {
-		name = "@"
+	msg, err := p.toStackPathRecord(endpoint, domain, target)
+	if err != nil {
+		return err
 	}
 
-	msg.SetName(name)
-	msg.SetType(dns.ZoneRecordType(endpoint.RecordType))
-	msg.SetTtl(int32(endpoint.RecordTTL))
-	msg.SetData(target)
-
-	log.Infof("Creating record " + name + "." + domain + " " + endpoint.RecordType + " " + target + " " + fmt.Sprint(endpoint.RecordTTL))
+	log.Infof("Creating record " + *msg.Name + "." + domain + " " + endpoint.RecordType + " " + target + " " + fmt.Sprint(endpoint.RecordTTL))
 
 	a, r, err := p.client.ResourceRecordsApi.CreateZoneRecord(p.context, p.stackID, zoneID).ZoneUpdateZoneRecordMessage(*msg).Execute()
 
@@ -278,6 +380,30 @@ func (p *StackPathProvider) createTarget(zoneID string, domain string, endpoint
 	return nil
 }
 
+func (p *StackPathProvider) updateTarget(zoneID string, domain string, recordID string, endpoint *endpoint.Endpoint, target string) error {
+
+	msg, err := p.toStackPathRecord(endpoint, domain, target)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Updating record " + *msg.Name + "." + domain + " (ID:" + recordID + ") " + endpoint.RecordType + " " + target + " " + fmt.Sprint(endpoint.RecordTTL))
+
+	a, r, err := p.client.ResourceRecordsApi.UpdateZoneRecord(p.context, p.stackID, zoneID, recordID).ZoneUpdateZoneRecordMessage(*msg).Execute()
+
+	if err != nil {
+		log.Infof(err.Error())
+		r.Body.Close()
+		b, _ := io.ReadAll(r.Body)
+		log.Infof(string(b))
+		return err
+	}
+
+	log.Infof("Updated record " + *a.Record.Name + "." + domain + " (ID:" + *a.Record.Id + ")")
+
+	return nil
+}
+
 func (p *StackPathProvider) delete(endpoints []*endpoint.Endpoint, zones *[]dns.ZoneZone, zoneIdNameMap *provider.ZoneIDName, records *[]dns.ZoneZoneRecord) error {
 	log.Infof("Deleting %s record(s)", fmt.Sprint(len(endpoints)))
 
@@ -321,19 +447,146 @@ func (p *StackPathProvider) deleteTarget(zone string, record string) error {
 
 func (p *StackPathProvider) update(old []*endpoint.Endpoint, new []*endpoint.Endpoint, zones *[]dns.ZoneZone, zoneIdNameMap *provider.ZoneIDName, records *[]dns.ZoneZoneRecord) error {
 
-	err := p.create(new, zones, zoneIdNameMap)
-	if err != nil {
-		return err
+	if len(old) != len(new) {
+		return fmt.Errorf("update: UpdateOld and UpdateNew have different lengths (%d != %d)", len(old), len(new))
 	}
 
-	err = p.delete(old, zones, zoneIdNameMap, records)
-	if err != nil {
-		return err
+	for i, oldEndpoint := range old {
+		newEndpoint := new[i]
+
+		zoneID, _ := zoneIdNameMap.FindZone(newEndpoint.DNSName)
+		if zoneID == "" {
+			log.Debugf("Skipping update for %s because no hosted zone matching record DNS Name was detected", newEndpoint.DNSName)
+			continue
+		}
+		domain := (*zoneIdNameMap)[zoneID]
+
+		for _, op := range planUpdateOps(oldEndpoint, newEndpoint, domain, records) {
+			if p.dryRun {
+				log.Infof("Would have updated record: %s %s %s %s", newEndpoint.DNSName, newEndpoint.RecordType, op.target, fmt.Sprint(newEndpoint.RecordTTL))
+				continue
+			}
+
+			switch op.kind {
+			case opPatch:
+				if err := p.updateTarget(zoneID, domain, op.recordID, newEndpoint, op.target); err != nil {
+					return err
+				}
+			case opCreate:
+				if err := p.createTarget(zoneID, domain, newEndpoint, op.target); err != nil {
+					return err
+				}
+			case opDelete:
+				if err := p.deleteTarget(zoneID, op.recordID); err != nil {
+					return err
+				}
+			}
+		}
 	}
 
 	return nil
 }
 
+type updateOpKind int
+
+const (
+	opPatch updateOpKind = iota
+	opCreate
+	opDelete
+)
+
+// updateOp describes a single action needed to reconcile one target of an
+// UpdateOld/UpdateNew endpoint pair.
+type updateOp struct {
+	kind     updateOpKind
+	recordID string
+	target   string
+}
+
+// planUpdateOps pairs oldEndpoint's targets with newEndpoint's targets
+// positionally and resolves each pair to a PATCH (opPatch) wherever the
+// existing record can be found via recordFromTarget, which keeps the
+// update atomic instead of the previous create(new)+delete(old). It falls
+// back to create-then-delete only when the prior record can't be resolved
+// by its exact old target value (e.g. no live record's data equals the old
+// target; a changed target whose old record exists is still PATCHed): in that case
+// it still tries to locate the stale record by name/type alone via
+// recordIDByNameType so the create is paired with an opDelete instead of
+// leaking a duplicate, and emits opDelete for any old targets that no
+// longer have a corresponding new target.
+func planUpdateOps(oldEndpoint, newEndpoint *endpoint.Endpoint, domain string, records *[]dns.ZoneZoneRecord) []updateOp {
+
+	paired := len(oldEndpoint.Targets)
+	if len(newEndpoint.Targets) < paired {
+		paired = len(newEndpoint.Targets)
+	}
+
+	claimed := map[string]bool{}
+	var ops []updateOp
+
+	for i := 0; i < paired; i++ {
+		oldTarget := oldEndpoint.Targets[i]
+		newTarget := newEndpoint.Targets[i]
+
+		recordID, err := recordFromTarget(oldEndpoint, oldTarget, records, domain)
+		if err == nil {
+			claimed[recordID] = true
+			ops = append(ops, updateOp{kind: opPatch, recordID: recordID, target: newTarget})
+			continue
+		}
+
+		ops = append(ops, updateOp{kind: opCreate, target: newTarget})
+		if recordID, ok := recordIDByNameType(oldEndpoint, domain, records, claimed); ok {
+			claimed[recordID] = true
+			ops = append(ops, updateOp{kind: opDelete, recordID: recordID})
+		}
+	}
+
+	for i := paired; i < len(newEndpoint.Targets); i++ {
+		ops = append(ops, updateOp{kind: opCreate, target: newEndpoint.Targets[i]})
+	}
+
+	for i := paired; i < len(oldEndpoint.Targets); i++ {
+		recordID, err := recordFromTarget(oldEndpoint, oldEndpoint.Targets[i], records, domain)
+		if err == nil {
+			claimed[recordID] = true
+			ops = append(ops, updateOp{kind: opDelete, recordID: recordID})
+			continue
+		}
+
+		if recordID, ok := recordIDByNameType(oldEndpoint, domain, records, claimed); ok {
+			claimed[recordID] = true
+			ops = append(ops, updateOp{kind: opDelete, recordID: recordID})
+		}
+	}
+
+	return ops
+}
+
+// recordIDByNameType is a best-effort fallback for recordFromTarget: it
+// looks up a record solely by name/type, ignoring its data, and is used
+// when the exact target value can't be matched (e.g. the live record's data
+// no longer equals the old target). claimed excludes record IDs already assigned to another op
+// in this same plan so the same stale record isn't deleted twice.
+func recordIDByNameType(ep *endpoint.Endpoint, domain string, records *[]dns.ZoneZoneRecord, claimed map[string]bool) (string, bool) {
+	name, err := extractRecordName(ep.DNSName, domain)
+	if err != nil {
+		return "", false
+	}
+
+	for _, record := range *records {
+		if record.GetName() != name || record.GetType() != ep.RecordType {
+			continue
+		}
+		if claimed[record.GetId()] {
+			continue
+		}
+		return record.GetId(), true
+	}
+
+	return "", false
+}
+
 func (p *StackPathProvider) zones() ([]dns.ZoneZone, error) {
 
 	zoneResponse, _, err := p.getZones()
@@ -359,10 +612,48 @@ func (p *StackPathProvider) zones() ([]dns.ZoneZone, error) {
 func (p *StackPathProvider) getZones() (dns.ZoneGetZonesResponse, *http.Response, error) {
 
 	if p.testing {
-		return testGetZoneRecords, nil, nil
+		return testGetZonesResponse, nil, nil
 	}
 
-	return p.client.ZonesApi.GetZones(p.context, p.stackID).Execute()
+	resp, err := paginateZones(p.pageSize, func(endCursor string) (dns.ZoneGetZonesResponse, *http.Response, error) {
+		req := p.client.ZonesApi.GetZones(p.context, p.stackID).PageRequestFirst(p.pageSize)
+		if endCursor != "" {
+			req = req.PageRequestAfter(endCursor)
+		}
+		return req.Execute()
+	})
+
+	return resp, nil, err
+}
+
+// paginateZones drives fetchPage until PageInfo.HasNextPage is false,
+// aggregating Zones across pages. fetchPage is given the EndCursor of the
+// previous page ("" for the first request).
+func paginateZones(pageSize int32, fetchPage func(endCursor string) (dns.ZoneGetZonesResponse, *http.Response, error)) (dns.ZoneGetZonesResponse, error) {
+
+	var allZones []dns.ZoneZone
+	aggregated := dns.ZoneGetZonesResponse{}
+	endCursor := ""
+
+	for {
+		resp, _, err := fetchPage(endCursor)
+		if err != nil {
+			return dns.ZoneGetZonesResponse{}, err
+		}
+
+		allZones = append(allZones, resp.GetZones()...)
+		aggregated = resp
+
+		pageInfo := resp.GetPageInfo()
+		if !pageInfo.GetHasNextPage() {
+			break
+		}
+		endCursor = pageInfo.GetEndCursor()
+	}
+
+	aggregated.Zones = &allZones
+
+	return aggregated, nil
 }
 
 // Merge Endpoints with the same Name and Type into a single endpoint with
@@ -392,6 +683,7 @@ func mergeEndpointsByNameType(endpoints []*endpoint.Endpoint) []*endpoint.Endpoi
 		}
 
 		e := endpoint.NewEndpoint(dnsName, recordType, targets...)
+		e.Labels = endpoints[0].Labels
 		result = append(result, e)
 	}
 
@@ -414,18 +706,233 @@ func endpointsByZoneId(zoneNameIDMapper provider.ZoneIDName, endpoints []*endpoi
 	return endpointsByZone
 }
 
+// recordTypeCAA names a record type StackPath supports but that
+// provider.SupportedRecordType does not yet recognize. SOA is out of scope
+// here and deliberately left unmanaged: it's infrastructure metadata owned
+// by StackPath itself, and external-dns would otherwise try to delete it on
+// every reconciliation since no source ever declares a matching SOA
+// endpoint.
+const (
+	recordTypeMX  = "MX"
+	recordTypeSRV = "SRV"
+	recordTypeCAA = "CAA"
+)
+
+// stackPathSupportedRecordType extends provider.SupportedRecordType with the
+// record types StackPath round-trips via structured fields (toStackPathRecord
+// / fromStackPathRecord) rather than the flat type/name/data/ttl shape.
+func stackPathSupportedRecordType(recordType string) bool {
+	switch recordType {
+	case recordTypeMX, recordTypeCAA:
+		return true
+	default:
+		return provider.SupportedRecordType(recordType)
+	}
+}
+
+// toStackPathRecord builds the create/update message for endpoint's target,
+// populating StackPath's typed Priority/Weight/Port/Flags/Tag fields for
+// record types that carry structured data instead of stuffing the whole
+// external-dns target string into Data. When p.useLabelRegistry is set, the
+// endpoint's owner/resource ownership metadata is carried in the record's
+// Labels instead of a shared TXT registry record.
+func (p *StackPathProvider) toStackPathRecord(ep *endpoint.Endpoint, domain string, target string) (*dns.ZoneUpdateZoneRecordMessage, error) {
+
+	name, err := extractRecordName(ep.DNSName, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := dns.NewZoneUpdateZoneRecordMessage()
+	msg.SetName(name)
+	msg.SetType(dns.ZoneRecordType(ep.RecordType))
+	msg.SetTtl(int32(ep.RecordTTL))
+
+	switch ep.RecordType {
+	case recordTypeMX:
+		priority, host, err := parseMXTarget(target)
+		if err != nil {
+			return nil, err
+		}
+		msg.SetPriority(priority)
+		msg.SetData(host)
+	case recordTypeSRV:
+		priority, weight, port, host, err := parseSRVTarget(target)
+		if err != nil {
+			return nil, err
+		}
+		msg.SetPriority(priority)
+		msg.SetWeight(weight)
+		msg.SetPort(port)
+		msg.SetData(host)
+	case recordTypeCAA:
+		flags, tag, value, err := parseCAATarget(target)
+		if err != nil {
+			return nil, err
+		}
+		msg.SetFlags(flags)
+		msg.SetTag(tag)
+		msg.SetData(value)
+	default:
+		msg.SetData(target)
+	}
+
+	if p.useLabelRegistry {
+		if labels := ownershipLabels(ep.Labels); len(labels) > 0 {
+			msg.SetLabels(labels)
+		}
+	}
+
+	return msg, nil
+}
+
+// ownershipLabels narrows ep's labels down to the owner/resource keys the
+// LabelRegistry mode round-trips through StackPath's record Labels.
+func ownershipLabels(epLabels endpoint.Labels) map[string]string {
+	labels := map[string]string{}
+
+	if owner, ok := epLabels[endpoint.OwnerLabelKey]; ok {
+		labels[endpoint.OwnerLabelKey] = owner
+	}
+	if resource, ok := epLabels[endpoint.ResourceLabelKey]; ok {
+		labels[endpoint.ResourceLabelKey] = resource
+	}
+
+	return labels
+}
+
+// ownershipLabelsFromRecord reconstructs endpoint.Labels from a StackPath
+// record's Labels, the inverse of ownershipLabels.
+func ownershipLabelsFromRecord(record dns.ZoneZoneRecord) endpoint.Labels {
+	labels := endpoint.Labels{}
+
+	for k, v := range record.GetLabels() {
+		if k == endpoint.OwnerLabelKey || k == endpoint.ResourceLabelKey {
+			labels[k] = v
+		}
+	}
+
+	return labels
+}
+
+// fromStackPathRecord is the inverse of toStackPathRecord: it reconstructs
+// the flat external-dns target string for a StackPath record, pulling the
+// structured fields back into RFC field order for types that carry them.
+func fromStackPathRecord(record dns.ZoneZoneRecord) string {
+	switch record.GetType() {
+	case recordTypeMX:
+		return fmt.Sprintf("%d %s", record.GetPriority(), record.GetData())
+	case recordTypeSRV:
+		return fmt.Sprintf("%d %d %d %s", record.GetPriority(), record.GetWeight(), record.GetPort(), record.GetData())
+	case recordTypeCAA:
+		return fmt.Sprintf("%d %s %s", record.GetFlags(), record.GetTag(), record.GetData())
+	default:
+		return record.GetData()
+	}
+}
+
+// parseMXTarget parses an external-dns MX target, formatted "priority target".
+func parseMXTarget(target string) (int32, string, error) {
+	fields := strings.Fields(target)
+	if len(fields) != 2 {
+		return 0, "", fmt.Errorf("invalid MX target %q: expected \"priority target\"", target)
+	}
+
+	priority, err := strconv.ParseInt(fields[0], 10, 32)
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid MX priority in %q: %w", target, err)
+	}
+
+	return int32(priority), fields[1], nil
+}
+
+// parseSRVTarget parses an external-dns SRV target, formatted
+// "priority weight port target".
+func parseSRVTarget(target string) (int32, int32, int32, string, error) {
+	fields := strings.Fields(target)
+	if len(fields) != 4 {
+		return 0, 0, 0, "", fmt.Errorf("invalid SRV target %q: expected \"priority weight port target\"", target)
+	}
+
+	values := make([]int32, 3)
+	for i := 0; i < 3; i++ {
+		v, err := strconv.ParseInt(fields[i], 10, 32)
+		if err != nil {
+			return 0, 0, 0, "", fmt.Errorf("invalid SRV field in %q: %w", target, err)
+		}
+		values[i] = int32(v)
+	}
+
+	return values[0], values[1], values[2], fields[3], nil
+}
+
+// parseCAATarget parses an external-dns CAA target, formatted
+// "flags tag value".
+func parseCAATarget(target string) (int32, string, string, error) {
+	fields := strings.SplitN(target, " ", 3)
+	if len(fields) != 3 {
+		return 0, "", "", fmt.Errorf("invalid CAA target %q: expected \"flags tag value\"", target)
+	}
+
+	flags, err := strconv.ParseInt(fields[0], 10, 32)
+	if err != nil {
+		return 0, "", "", fmt.Errorf("invalid CAA flags in %q: %w", target, err)
+	}
+
+	return int32(flags), fields[1], fields[2], nil
+}
+
+// extractRecordName derives the StackPath record name (the part of fqdn left
+// of zoneDomain) for a record belonging to zoneDomain. It returns "@" for
+// the zone apex and an error if fqdn does not actually belong to zoneDomain
+// -- e.g. when two managed zones share a suffix (example.com and
+// staging.example.com) and the wrong zoneDomain was passed in, a plain
+// strings.TrimSuffix would silently produce the wrong name instead of
+// failing. Callers should resolve zoneDomain via the zone's own entry in
+// provider.ZoneIDName (which already picks the longest matching zone for a
+// given fqdn) before calling this.
+func extractRecordName(fqdn, zoneDomain string) (string, error) {
+	fqdn = strings.TrimSuffix(fqdn, ".")
+	zoneDomain = strings.TrimSuffix(zoneDomain, ".")
+
+	if fqdn == "" || fqdn == zoneDomain {
+		return "@", nil
+	}
+
+	suffix := "." + zoneDomain
+	if !strings.HasSuffix(fqdn, suffix) {
+		return "", fmt.Errorf("%q is not a member of zone %q", fqdn, zoneDomain)
+	}
+
+	return strings.TrimSuffix(fqdn, suffix), nil
+}
+
 func recordFromTarget(endpoint *endpoint.Endpoint, target string, records *[]dns.ZoneZoneRecord, domain string) (string, error) {
 
-	var name string
+	name, err := extractRecordName(endpoint.DNSName, domain)
+	if err != nil {
+		return "", err
+	}
 
-	if endpoint.DNSName == "" {
-		name = "@"
-	} else {
-		name = strings.TrimSuffix(endpoint.DNSName, "."+domain)
+	want := target
+	switch endpoint.RecordType {
+	case recordTypeMX, recordTypeSRV, recordTypeCAA:
+		// These are reconstructed as the full composite string (e.g. CAA's
+		// quoted value), so trimming stray quotes here would corrupt it --
+		// see fromStackPathRecord.
+	default:
+		want = strings.Trim(target, "\\\"")
 	}
 
 	for _, record := range *records {
-		if record.GetName() == name && record.GetType() == endpoint.RecordType && record.GetData() == strings.Trim(target, "\\\"") /*&& record.GetTtl() == int32(endpoint.RecordTTL)*/ {
+		if record.GetName() != name || record.GetType() != endpoint.RecordType {
+			continue
+		}
+		// Compare against the reconstructed flat target rather than
+		// record.GetData() directly: MX/SRV/CAA store only part of the
+		// target (host/value) in Data, with the rest in their typed
+		// Priority/Weight/Port/Flags/Tag fields.
+		if fromStackPathRecord(record) == want /*&& record.GetTtl() == int32(endpoint.RecordTTL)*/ {
 			return *record.Id, nil
 		}
 	}
@@ -502,7 +1009,9 @@ var (
 	testGetZonesHasPreviousPage = false
 	testGetZonesHasNextPage     = false
 	testGetZonesEndCursor       = "2"
-	testGetZoneRecords          = dns.ZoneGetZonesResponse{
+	// testGetZonesResponse is returned by getZones when StackPathConfig.Testing
+	// is set, bypassing the real paginated API call.
+	testGetZonesResponse = dns.ZoneGetZonesResponse{
 		PageInfo: &dns.PaginationPageInfo{
 			TotalCount:      &testGetZonesTotalCount,
 			HasPreviousPage: &testGetZonesHasPreviousPage,
@@ -0,0 +1,407 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stackpath
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"sigs.k8s.io/external-dns/endpoint"
+
+	"github.com/wmarchesi123/stackpath-go/pkg/dns"
+)
+
+// newTestRecord builds a minimal dns.ZoneZoneRecord for pagination fixtures.
+func newTestRecord(id, name, recordType, data string) dns.ZoneZoneRecord {
+	return dns.ZoneZoneRecord{
+		Id:   &id,
+		Name: &name,
+		Type: (*dns.ZoneRecordType)(&recordType),
+		Data: &data,
+	}
+}
+
+func zoneRecordsPage(records []dns.ZoneZoneRecord, hasNextPage bool, endCursor string) dns.ZoneGetZoneRecordsResponse {
+	return dns.ZoneGetZoneRecordsResponse{
+		PageInfo: &dns.PaginationPageInfo{
+			HasNextPage: &hasNextPage,
+			EndCursor:   &endCursor,
+		},
+		Records: &records,
+	}
+}
+
+func TestPaginateZoneRecordsSinglePage(t *testing.T) {
+	page := zoneRecordsPage([]dns.ZoneZoneRecord{newTestRecord("1", "www", "A", "1.2.3.4")}, false, "")
+
+	calls := 0
+	resp, err := paginateZoneRecords(defaultPageSize, func(endCursor string) (dns.ZoneGetZoneRecordsResponse, *http.Response, error) {
+		calls++
+		assert.Equal(t, "", endCursor)
+		return page, nil, nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, calls)
+	assert.Len(t, resp.GetRecords(), 1)
+}
+
+func TestPaginateZoneRecordsMultiplePages(t *testing.T) {
+	page1 := zoneRecordsPage([]dns.ZoneZoneRecord{newTestRecord("1", "www", "A", "1.2.3.4")}, true, "cursor-1")
+	page2 := zoneRecordsPage([]dns.ZoneZoneRecord{newTestRecord("2", "api", "A", "5.6.7.8")}, false, "")
+
+	var seenCursors []string
+	resp, err := paginateZoneRecords(defaultPageSize, func(endCursor string) (dns.ZoneGetZoneRecordsResponse, *http.Response, error) {
+		seenCursors = append(seenCursors, endCursor)
+		if endCursor == "" {
+			return page1, nil, nil
+		}
+		return page2, nil, nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"", "cursor-1"}, seenCursors)
+	if assert.Len(t, resp.GetRecords(), 2) {
+		assert.Equal(t, "www", resp.GetRecords()[0].GetName())
+		assert.Equal(t, "api", resp.GetRecords()[1].GetName())
+	}
+}
+
+func TestPaginateZonesMultiplePages(t *testing.T) {
+	totalCount := "3"
+	hasNext := true
+	noNext := false
+	cursor1 := "cursor-1"
+	emptyCursor := ""
+
+	page1 := dns.ZoneGetZonesResponse{
+		PageInfo: &dns.PaginationPageInfo{TotalCount: &totalCount, HasNextPage: &hasNext, EndCursor: &cursor1},
+		Zones:    &[]dns.ZoneZone{testGetZonesZones[0], testGetZonesZones[1]},
+	}
+	page2 := dns.ZoneGetZonesResponse{
+		PageInfo: &dns.PaginationPageInfo{TotalCount: &totalCount, HasNextPage: &noNext, EndCursor: &emptyCursor},
+		Zones:    &[]dns.ZoneZone{testGetZonesZones[2]},
+	}
+
+	resp, err := paginateZones(defaultPageSize, func(endCursor string) (dns.ZoneGetZonesResponse, *http.Response, error) {
+		if endCursor == "" {
+			return page1, nil, nil
+		}
+		return page2, nil, nil
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, resp.GetZones(), 3)
+}
+
+func TestPlanUpdateOpsPatchesPureTTLChange(t *testing.T) {
+	domain := "example.com"
+	records := []dns.ZoneZoneRecord{newTestRecord("rec-1", "www", "A", "1.2.3.4")}
+
+	oldEndpoint := endpoint.NewEndpointWithTTL("www.example.com", "A", 60, "1.2.3.4")
+	newEndpoint := endpoint.NewEndpointWithTTL("www.example.com", "A", 300, "1.2.3.4")
+
+	ops := planUpdateOps(oldEndpoint, newEndpoint, domain, &records)
+
+	if assert.Len(t, ops, 1) {
+		assert.Equal(t, opPatch, ops[0].kind)
+		assert.Equal(t, "rec-1", ops[0].recordID)
+		assert.Equal(t, "1.2.3.4", ops[0].target)
+	}
+}
+
+func TestExtractRecordName(t *testing.T) {
+	tests := []struct {
+		name       string
+		fqdn       string
+		zoneDomain string
+		want       string
+		wantErr    bool
+	}{
+		{name: "subdomain", fqdn: "www.example.com", zoneDomain: "example.com", want: "www"},
+		{name: "apex", fqdn: "example.com", zoneDomain: "example.com", want: "@"},
+		{name: "apex with trailing dot", fqdn: "example.com.", zoneDomain: "example.com", want: "@"},
+		{name: "empty fqdn treated as apex", fqdn: "", zoneDomain: "example.com", want: "@"},
+		{name: "nested zone", fqdn: "www.staging.example.com", zoneDomain: "staging.example.com", want: "www"},
+		{name: "mismatched suffix sharing a substring", fqdn: "www.notexample.com", zoneDomain: "example.com", wantErr: true},
+		{name: "unrelated domain", fqdn: "www.other.com", zoneDomain: "example.com", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := extractRecordName(tt.fqdn, tt.zoneDomain)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestStackPathSupportedRecordType(t *testing.T) {
+	tests := []struct {
+		recordType string
+		want       bool
+	}{
+		{recordType: "MX", want: true},
+		{recordType: "SRV", want: true},
+		{recordType: "CAA", want: true},
+		{recordType: "A", want: true},
+		{recordType: "SOA", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.recordType, func(t *testing.T) {
+			assert.Equal(t, tt.want, stackPathSupportedRecordType(tt.recordType))
+		})
+	}
+}
+
+func TestToStackPathRecordStructuredFields(t *testing.T) {
+	domain := "example.com"
+
+	tests := []struct {
+		name       string
+		recordType string
+		target     string
+		assert     func(t *testing.T, msg *dns.ZoneUpdateZoneRecordMessage)
+	}{
+		{
+			name:       "MX",
+			recordType: recordTypeMX,
+			target:     "10 mail.example.com",
+			assert: func(t *testing.T, msg *dns.ZoneUpdateZoneRecordMessage) {
+				assert.EqualValues(t, 10, msg.GetPriority())
+				assert.Equal(t, "mail.example.com", msg.GetData())
+			},
+		},
+		{
+			name:       "SRV",
+			recordType: recordTypeSRV,
+			target:     "10 20 5060 sip.example.com",
+			assert: func(t *testing.T, msg *dns.ZoneUpdateZoneRecordMessage) {
+				assert.EqualValues(t, 10, msg.GetPriority())
+				assert.EqualValues(t, 20, msg.GetWeight())
+				assert.EqualValues(t, 5060, msg.GetPort())
+				assert.Equal(t, "sip.example.com", msg.GetData())
+			},
+		},
+		{
+			name:       "CAA",
+			recordType: recordTypeCAA,
+			target:     `0 issue "letsencrypt.org"`,
+			assert: func(t *testing.T, msg *dns.ZoneUpdateZoneRecordMessage) {
+				assert.EqualValues(t, 0, msg.GetFlags())
+				assert.Equal(t, "issue", msg.GetTag())
+				assert.Equal(t, `"letsencrypt.org"`, msg.GetData())
+			},
+		},
+		{
+			name:       "A falls through to flat Data",
+			recordType: "A",
+			target:     "1.2.3.4",
+			assert: func(t *testing.T, msg *dns.ZoneUpdateZoneRecordMessage) {
+				assert.Equal(t, "1.2.3.4", msg.GetData())
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ep := endpoint.NewEndpointWithTTL("www.example.com", tt.recordType, 300, tt.target)
+			msg, err := (&StackPathProvider{}).toStackPathRecord(ep, domain, tt.target)
+			assert.NoError(t, err)
+			tt.assert(t, msg)
+		})
+	}
+}
+
+func TestToStackPathRecordInvalidTargets(t *testing.T) {
+	domain := "example.com"
+
+	tests := []struct {
+		name       string
+		recordType string
+		target     string
+	}{
+		{name: "MX missing priority", recordType: recordTypeMX, target: "mail.example.com"},
+		{name: "SRV too few fields", recordType: recordTypeSRV, target: "10 20 sip.example.com"},
+		{name: "CAA missing value", recordType: recordTypeCAA, target: "0 issue"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ep := endpoint.NewEndpointWithTTL("www.example.com", tt.recordType, 300, tt.target)
+			_, err := (&StackPathProvider{}).toStackPathRecord(ep, domain, tt.target)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestFromStackPathRecordRoundTrip(t *testing.T) {
+	domain := "example.com"
+
+	tests := []struct {
+		name       string
+		recordType string
+		target     string
+	}{
+		{name: "MX", recordType: recordTypeMX, target: "10 mail.example.com"},
+		{name: "SRV", recordType: recordTypeSRV, target: "10 20 5060 sip.example.com"},
+		{name: "CAA", recordType: recordTypeCAA, target: `0 issue "letsencrypt.org"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ep := endpoint.NewEndpointWithTTL("www.example.com", tt.recordType, 300, tt.target)
+			msg, err := (&StackPathProvider{}).toStackPathRecord(ep, domain, tt.target)
+			assert.NoError(t, err)
+
+			record := dns.ZoneZoneRecord{
+				Name:     msg.Name,
+				Type:     (*dns.ZoneRecordType)(&tt.recordType),
+				Data:     msg.Data,
+				Priority: msg.Priority,
+				Weight:   msg.Weight,
+				Port:     msg.Port,
+				Flags:    msg.Flags,
+				Tag:      msg.Tag,
+			}
+
+			assert.Equal(t, tt.target, fromStackPathRecord(record))
+		})
+	}
+}
+
+func TestRecordFromTargetQuotedCAA(t *testing.T) {
+	domain := "example.com"
+	target := `0 issue "letsencrypt.org"`
+
+	id, name, recordType, data, tag := "record-id", "www", recordTypeCAA, `"letsencrypt.org"`, "issue"
+	var flags int32 = 0
+
+	records := []dns.ZoneZoneRecord{
+		{
+			Id:    &id,
+			Name:  &name,
+			Type:  (*dns.ZoneRecordType)(&recordType),
+			Data:  &data,
+			Flags: &flags,
+			Tag:   &tag,
+		},
+	}
+
+	ep := endpoint.NewEndpointWithTTL("www.example.com", recordTypeCAA, 300, target)
+
+	got, err := recordFromTarget(ep, target, &records, domain)
+	assert.NoError(t, err)
+	assert.Equal(t, id, got)
+}
+
+func TestPlanUpdateOpsFallsBackToCreateWhenRecordUnresolved(t *testing.T) {
+	domain := "example.com"
+	records := []dns.ZoneZoneRecord{}
+
+	oldEndpoint := endpoint.NewEndpointWithTTL("www.example.com", "A", 60, "1.2.3.4")
+	newEndpoint := endpoint.NewEndpointWithTTL("www.example.com", "A", 60, "5.6.7.8")
+
+	ops := planUpdateOps(oldEndpoint, newEndpoint, domain, &records)
+
+	if assert.Len(t, ops, 1) {
+		assert.Equal(t, opCreate, ops[0].kind)
+		assert.Equal(t, "5.6.7.8", ops[0].target)
+	}
+}
+
+func TestPlanUpdateOpsFallsBackToCreateThenDeleteWhenRecordStale(t *testing.T) {
+	domain := "example.com"
+
+	// This record is wired to the endpoint by name/type only -- its data
+	// ("9.9.9.9") deliberately doesn't match either the old or new target,
+	// so recordFromTarget can't resolve it and planUpdateOps must fall back
+	// to recordIDByNameType to find it for deletion.
+	id, name, recordType, data := "stale-id", "www", "A", "9.9.9.9"
+	records := []dns.ZoneZoneRecord{
+		{
+			Id:   &id,
+			Name: &name,
+			Type: (*dns.ZoneRecordType)(&recordType),
+			Data: &data,
+		},
+	}
+
+	oldEndpoint := endpoint.NewEndpointWithTTL("www.example.com", "A", 60, "1.2.3.4")
+	newEndpoint := endpoint.NewEndpointWithTTL("www.example.com", "A", 60, "5.6.7.8")
+
+	ops := planUpdateOps(oldEndpoint, newEndpoint, domain, &records)
+
+	if assert.Len(t, ops, 2) {
+		assert.Equal(t, opCreate, ops[0].kind)
+		assert.Equal(t, "5.6.7.8", ops[0].target)
+		assert.Equal(t, opDelete, ops[1].kind)
+		assert.Equal(t, "stale-id", ops[1].recordID)
+	}
+}
+
+func TestLabelRegistryRoundTrip(t *testing.T) {
+	domain := "example.com"
+
+	ep := endpoint.NewEndpointWithTTL("www.example.com", "A", 300, "1.2.3.4")
+	ep.Labels = endpoint.Labels{
+		endpoint.OwnerLabelKey:    "default",
+		endpoint.ResourceLabelKey: "ingress/default/www",
+		"unrelated":               "dropped",
+	}
+
+	p := &StackPathProvider{useLabelRegistry: true}
+
+	msg, err := p.toStackPathRecord(ep, domain, "1.2.3.4")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		endpoint.OwnerLabelKey:    "default",
+		endpoint.ResourceLabelKey: "ingress/default/www",
+	}, msg.GetLabels())
+
+	record := dns.ZoneZoneRecord{
+		Name:   msg.Name,
+		Type:   msg.Type,
+		Data:   msg.Data,
+		Labels: msg.Labels,
+	}
+
+	assert.Equal(t, endpoint.Labels{
+		endpoint.OwnerLabelKey:    "default",
+		endpoint.ResourceLabelKey: "ingress/default/www",
+	}, ownershipLabelsFromRecord(record))
+}
+
+func TestLabelRegistryDisabledOmitsLabels(t *testing.T) {
+	domain := "example.com"
+
+	ep := endpoint.NewEndpointWithTTL("www.example.com", "A", 300, "1.2.3.4")
+	ep.Labels = endpoint.Labels{endpoint.OwnerLabelKey: "default"}
+
+	p := &StackPathProvider{useLabelRegistry: false}
+
+	msg, err := p.toStackPathRecord(ep, domain, "1.2.3.4")
+	assert.NoError(t, err)
+	assert.Nil(t, msg.Labels)
+}